@@ -0,0 +1,85 @@
+package fastcodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+type podStruct struct {
+	A uint32
+	B float64
+	C int16
+}
+
+func BenchmarkBytesUint32(b *testing.B) {
+	xs := make([]uint32, 1000)
+	for i := range xs {
+		xs[i] = uint32(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Bytes(xs)
+	}
+}
+
+func BenchmarkBinaryWriteUint32(b *testing.B) {
+	xs := make([]uint32, 1000)
+	for i := range xs {
+		xs[i] = uint32(i)
+	}
+	buf := new(bytes.Buffer)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		binary.Write(buf, binary.LittleEndian, xs)
+	}
+}
+
+func BenchmarkBytesFloat64(b *testing.B) {
+	xs := make([]float64, 1000)
+	for i := range xs {
+		xs[i] = float64(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Bytes(xs)
+	}
+}
+
+func BenchmarkBinaryWriteFloat64(b *testing.B) {
+	xs := make([]float64, 1000)
+	for i := range xs {
+		xs[i] = float64(i)
+	}
+	buf := new(bytes.Buffer)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		binary.Write(buf, binary.LittleEndian, xs)
+	}
+}
+
+func BenchmarkBytesPODStruct(b *testing.B) {
+	xs := make([]podStruct, 1000)
+	for i := range xs {
+		xs[i] = podStruct{A: uint32(i), B: float64(i), C: int16(i)}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Bytes(xs)
+	}
+}
+
+func BenchmarkBinaryWritePODStruct(b *testing.B) {
+	xs := make([]podStruct, 1000)
+	for i := range xs {
+		xs[i] = podStruct{A: uint32(i), B: float64(i), C: int16(i)}
+	}
+	buf := new(bytes.Buffer)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		binary.Write(buf, binary.LittleEndian, xs)
+	}
+}
@@ -0,0 +1,123 @@
+package fastcodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"unsafe"
+)
+
+// Endian selects the byte order a Codec encodes to and decodes from.
+type Endian int
+
+const (
+	// NativeEndian uses the host's byte order, enabling the unsafe
+	// punning path in Encode/Decode.
+	NativeEndian Endian = iota
+	LittleEndian
+	BigEndian
+)
+
+// hostEndian is resolved once at init time by inspecting how the
+// runtime lays out a multi-byte integer in memory.
+var hostEndian = func() Endian {
+	var x uint16 = 1
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return LittleEndian
+	}
+	return BigEndian
+}()
+
+// Option configures a Codec constructed by NewCodec.
+type Option func(*codecConfig)
+
+type codecConfig struct {
+	endian Endian
+}
+
+// WithEndian requests that a Codec encode to (and decode from) the
+// given byte order, rather than the host's native order. On a host
+// whose native order doesn't match, this forces Encode/Decode onto the
+// safe encoding/binary path instead of unsafe punning.
+func WithEndian(e Endian) Option {
+	return func(c *codecConfig) { c.endian = e }
+}
+
+// Codec encodes and decodes []T to and from []byte, caching the checks
+// Bytes/Slice would otherwise repeat on every call: that T is pointer-free
+// and fixed-size, and which byte order to target.
+type Codec[T any] struct {
+	size   int
+	endian Endian
+}
+
+// NewCodec constructs a Codec for T. It panics if T contains pointers,
+// for the same reason Bytes and Slice do - there's no such thing as a
+// safe byte-level reinterpretation of a pointer.
+func NewCodec[T any](opts ...Option) *Codec[T] {
+	var zero T
+	if containsPointer(reflect.TypeOf(zero)) {
+		panic("fastcodec: NewCodec: type contains pointers")
+	}
+
+	cfg := codecConfig{endian: NativeEndian}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.endian == NativeEndian {
+		cfg.endian = hostEndian
+	}
+
+	return &Codec[T]{
+		size:   int(unsafe.Sizeof(zero)),
+		endian: cfg.endian,
+	}
+}
+
+// Encode returns xs as bytes in the Codec's configured byte order. If
+// that order matches the host's, the result aliases xs's backing array
+// exactly as Bytes does; otherwise Encode falls back to encoding/binary
+// and returns a fresh copy.
+func (c *Codec[T]) Encode(xs []T) []byte {
+	if c.endian == hostEndian {
+		return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(xs))), len(xs)*c.size)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, len(xs)*c.size))
+	if err := binary.Write(buf, c.byteOrder(), xs); err != nil {
+		panic("fastcodec: Encode: " + err.Error())
+	}
+	return buf.Bytes()
+}
+
+// Decode returns b as a []T, the inverse of Encode. len(b) must be a
+// multiple of T's encoded size: sizeof(T) when the Codec uses the host's
+// order, or T's encoding/binary size (which leaves out struct padding)
+// otherwise.
+func (c *Codec[T]) Decode(b []byte) []T {
+	if c.endian == hostEndian {
+		if len(b)%c.size != 0 {
+			panic("fastcodec: Decode: byte slice length is not a multiple of the element size")
+		}
+		return unsafe.Slice((*T)(unsafe.Pointer(unsafe.SliceData(b))), len(b)/c.size)
+	}
+	var zero T
+	size := binary.Size(zero)
+	if size <= 0 {
+		panic("fastcodec: Decode: type has no fixed encoding/binary size")
+	}
+	if len(b)%size != 0 {
+		panic("fastcodec: Decode: byte slice length is not a multiple of the element size")
+	}
+	out := make([]T, len(b)/size)
+	if err := binary.Read(bytes.NewReader(b), c.byteOrder(), out); err != nil {
+		panic("fastcodec: Decode: " + err.Error())
+	}
+	return out
+}
+
+func (c *Codec[T]) byteOrder() binary.ByteOrder {
+	if c.endian == BigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
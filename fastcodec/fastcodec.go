@@ -0,0 +1,77 @@
+// Package fastcodec reinterprets slices of fixed-size values as raw
+// bytes (and back) by aliasing the backing array, instead of copying
+// element-by-element through encoding/binary. The benchmark this grew
+// out of showed that punning beats binary.LittleEndian.PutUint64 in a
+// loop by a wide margin, at the cost of being tied to the host's native
+// byte order and memory layout.
+//
+// Bytes and Slice always alias memory in host order; use a Codec when
+// you need bytes in a specific order, since that may require an actual
+// copy on big-endian hosts.
+package fastcodec
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Bytes reinterprets xs as a []byte that aliases xs's backing array: no
+// copy is made, so writes through the result are visible in xs and vice
+// versa, and the result is only valid for as long as xs is.
+//
+// T must not contain pointers (see containsPointer) - Bytes panics if it
+// does, since punning a pointer's bit pattern into a byte slice isn't
+// meaningful and would hide a live reference from the garbage collector.
+func Bytes[T any](xs []T) []byte {
+	var zero T
+	if containsPointer(reflect.TypeOf(zero)) {
+		panic("fastcodec: Bytes: type contains pointers")
+	}
+	size := int(unsafe.Sizeof(zero))
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(xs))), len(xs)*size)
+}
+
+// Slice reinterprets b as a []T that aliases b's backing array, the
+// inverse of Bytes. len(b) must be a multiple of sizeof(T), or Slice
+// panics.
+func Slice[T any](b []byte) []T {
+	var zero T
+	if containsPointer(reflect.TypeOf(zero)) {
+		panic("fastcodec: Slice: type contains pointers")
+	}
+	size := int(unsafe.Sizeof(zero))
+	if len(b)%size != 0 {
+		panic("fastcodec: Slice: byte slice length is not a multiple of the element size")
+	}
+	return unsafe.Slice((*T)(unsafe.Pointer(unsafe.SliceData(b))), len(b)/size)
+}
+
+// containsPointer reports whether t is, or contains, anything the
+// garbage collector would need to track: a pointer, interface, map,
+// chan, func, string, slice, or unsafe.Pointer. Go has no type-level
+// constraint that expresses "no pointers anywhere in this type," so
+// Codec checks this once via reflection when it's constructed instead.
+//
+// t is nil when T itself is an interface type and the zero value passed
+// in is a nil interface (reflect.TypeOf returns nil for that), which
+// can't be punned into bytes any more safely than a concrete interface
+// value can - treat it the same as "contains a pointer".
+func containsPointer(t reflect.Type) bool {
+	if t == nil {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func,
+		reflect.Interface, reflect.Slice, reflect.String, reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return containsPointer(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if containsPointer(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
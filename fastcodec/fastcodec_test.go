@@ -0,0 +1,82 @@
+package fastcodec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBytesRoundTrip(t *testing.T) {
+	xs := []uint64{1, 2, 3, 0xdeadbeef}
+	b := Bytes(xs)
+	if len(b) != len(xs)*8 {
+		t.Fatalf("len(b) = %d, want %d", len(b), len(xs)*8)
+	}
+	back := Slice[uint64](b)
+	if !reflect.DeepEqual(back, xs) {
+		t.Fatalf("round trip = %v, want %v", back, xs)
+	}
+
+	// Bytes aliases xs; mutating one must be visible through the other.
+	b[0] = 0xff
+	if xs[0]&0xff == 1 {
+		t.Fatal("Bytes did not alias the backing array")
+	}
+}
+
+func TestBytesRejectsPointerTypes(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Bytes of a pointer-containing type did not panic")
+		}
+	}()
+	Bytes([]*int{new(int)})
+}
+
+func TestBytesRejectsInterfaceType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Bytes of an interface type did not panic")
+		}
+	}()
+	Bytes([]error{nil})
+}
+
+func TestSliceRejectsMisalignedLength(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Slice with a misaligned length did not panic")
+		}
+	}()
+	Slice[uint32]([]byte{1, 2, 3})
+}
+
+func TestCodecCrossEndian(t *testing.T) {
+	xs := []uint32{1, 256, 0x01020304}
+
+	le := NewCodec[uint32](WithEndian(LittleEndian))
+	be := NewCodec[uint32](WithEndian(BigEndian))
+
+	leBytes := le.Encode(xs)
+	beBytes := be.Encode(xs)
+	if reflect.DeepEqual(leBytes, beBytes) {
+		t.Fatal("little- and big-endian encodings should differ")
+	}
+
+	if got := le.Decode(leBytes); !reflect.DeepEqual(got, xs) {
+		t.Fatalf("LittleEndian round trip = %v, want %v", got, xs)
+	}
+	if got := be.Decode(beBytes); !reflect.DeepEqual(got, xs) {
+		t.Fatalf("BigEndian round trip = %v, want %v", got, xs)
+	}
+}
+
+func TestCodecStruct(t *testing.T) {
+	type point struct{ X, Y int32 }
+	c := NewCodec[point]()
+	xs := []point{{1, 2}, {3, 4}}
+	b := c.Encode(xs)
+	got := c.Decode(b)
+	if !reflect.DeepEqual(got, xs) {
+		t.Fatalf("round trip = %v, want %v", got, xs)
+	}
+}
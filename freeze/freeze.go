@@ -0,0 +1,174 @@
+// Package freeze promotes unsafe/freeze.go's single-int demo into a
+// general-purpose Freeze/Unfreeze pair: copy any pointed-to value onto
+// an mmap'd, mprotect'd read-only page, and panic instead of silently
+// corrupting memory (or crashing outright) if anything tries to write
+// through it afterward.
+package freeze
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// pageSize is used instead of os.Getpagesize to avoid yet another
+// import in a file that's already reaching well past what "os" was
+// meant for.
+var pageSize = unix.Getpagesize()
+
+// keepAlive retains, for each live frozen page, references to every Go
+// object reachable through pointers embedded in the frozen copy. Those
+// pointers are invisible to the garbage collector once they're sitting
+// inside mmap'd memory instead of a normal Go allocation, so without
+// this the referenced objects could be collected out from under a
+// frozen value that still points at them.
+var keepAlive sync.Map // map[unsafe.Pointer][]interface{}
+
+// Freeze returns a read-only copy of the value v points to. Any attempt
+// to write through the returned pointer panics instead of corrupting
+// memory or crashing the process outright - Freeze calls
+// debug.SetPanicOnFault on the calling goroutine to arrange that, so
+// callers that hand a frozen value to another goroutine must call
+// debug.SetPanicOnFault(true) there themselves before writing to it.
+//
+// v must be a pointer. Freeze rejects map values (Kind() == reflect.Map,
+// anywhere in v's type, including nested) because a map's bucket array
+// is itself mutated by ordinary reads - growth, evacuation, and even
+// iteration order all write through the map header - so there's no
+// fixed byte layout to freeze.
+func Freeze(v interface{}) interface{} {
+	debug.SetPanicOnFault(true)
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		panic("freeze: Freeze requires a pointer")
+	}
+	elem := rv.Elem()
+	t := elem.Type()
+	if containsMap(t) {
+		panic("freeze: cannot freeze a type containing a map")
+	}
+
+	size := int(t.Size())
+	n := size
+	if m := n % pageSize; m != 0 {
+		n += pageSize - m
+	}
+
+	frozen, err := unix.Mmap(-1, 0, n, unix.PROT_READ|unix.PROT_WRITE,
+		unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		panic(fmt.Sprintf("freeze: mmap: %v", err))
+	}
+
+	src := unsafe.Slice((*byte)(unsafe.Pointer(elem.UnsafeAddr())), size)
+	copy(frozen, src)
+
+	out := reflect.NewAt(t, unsafe.Pointer(&frozen[0]))
+
+	if refs := collectRefs(elem); len(refs) > 0 {
+		keepAlive.Store(unsafe.Pointer(&frozen[0]), refs)
+	}
+
+	if err := unix.Mprotect(frozen, unix.PROT_READ); err != nil {
+		panic(fmt.Sprintf("freeze: mprotect: %v", err))
+	}
+
+	return out.Interface()
+}
+
+// Unfreeze releases the page backing a value returned by Freeze. The
+// value (and any copies of it) must not be used afterward.
+func Unfreeze(v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		panic("freeze: Unfreeze requires a pointer")
+	}
+	size := int(rv.Elem().Type().Size())
+	n := size
+	if m := n % pageSize; m != 0 {
+		n += pageSize - m
+	}
+
+	p := unsafe.Pointer(rv.Pointer())
+	keepAlive.Delete(p)
+	if err := unix.Munmap(unsafe.Slice((*byte)(p), n)); err != nil {
+		panic(fmt.Sprintf("freeze: munmap: %v", err))
+	}
+}
+
+// containsMap reports whether t is, or contains, a map type.
+func containsMap(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return containsMap(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if containsMap(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collectRefs walks v and returns every pointer-ish value reachable
+// from it - pointers, slice backing arrays, interface payloads - boxed
+// so the garbage collector keeps them alive on v's behalf once v itself
+// has been copied out to unmanaged memory.
+func collectRefs(v reflect.Value) []interface{} {
+	var refs []interface{}
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		// A field reached through an unexported struct field carries a
+		// read-only flag that makes CanInterface (and so .Interface())
+		// panic, even though the field itself is perfectly readable. Since
+		// most real structs have at least one unexported field, rebuild
+		// an equivalent, unflagged Value from its address before doing
+		// anything else - every reflect.Value reachable below this point
+		// is either already exportable or was made so here.
+		if v.CanAddr() && !v.CanInterface() {
+			v = reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+		}
+		switch v.Kind() {
+		case reflect.Ptr:
+			if !v.IsNil() {
+				refs = append(refs, v.Interface())
+				walk(v.Elem())
+			}
+		case reflect.Interface:
+			if !v.IsNil() {
+				refs = append(refs, v.Interface())
+				// An interface's dynamic value isn't addressable, so copy
+				// it into a value that is before walking it; otherwise
+				// the rebuild above couldn't reach its unexported fields.
+				e := reflect.New(v.Elem().Type()).Elem()
+				e.Set(v.Elem())
+				walk(e)
+			}
+		case reflect.Slice:
+			if !v.IsNil() {
+				refs = append(refs, v.Interface())
+			}
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				walk(v.Field(i))
+			}
+		}
+	}
+	walk(v)
+	return refs
+}
@@ -0,0 +1,72 @@
+package freeze
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestFreezeStruct(t *testing.T) {
+	type point struct{ X, Y int }
+	p := &point{X: 3, Y: 4}
+	frozen := Freeze(p).(*point)
+	if *frozen != *p {
+		t.Fatalf("frozen value = %+v, want %+v", *frozen, *p)
+	}
+	Unfreeze(frozen)
+}
+
+func TestFreezeSlice(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5}
+	frozen := Freeze(&s).(*[]int)
+	if len(*frozen) != len(s) {
+		t.Fatalf("frozen slice len = %d, want %d", len(*frozen), len(s))
+	}
+	for i, v := range s {
+		if (*frozen)[i] != v {
+			t.Fatalf("frozen[%d] = %d, want %d", i, (*frozen)[i], v)
+		}
+	}
+	Unfreeze(frozen)
+}
+
+// TestFreezeKeepsPointeeAlive freezes a struct holding a pointer to
+// another heap object, through an unexported field (also exercising
+// collectRefs's unexported-field handling), then forces GC to confirm
+// the pointee is still reachable afterward via keepAlive - the mmap'd
+// page itself is invisible to the garbage collector's scanner.
+func TestFreezeKeepsPointeeAlive(t *testing.T) {
+	type holder struct {
+		p *int
+	}
+	v := 7
+	frozen := Freeze(&holder{p: &v}).(*holder)
+
+	runtime.GC()
+	runtime.GC()
+
+	if *frozen.p != 7 {
+		t.Fatalf("frozen.p = %d, want 7 (pointee was collected or corrupted)", *frozen.p)
+	}
+	Unfreeze(frozen)
+}
+
+func TestFreezeRejectsMap(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Freeze of a map did not panic")
+		}
+	}()
+	m := map[string]int{"a": 1}
+	Freeze(&m)
+}
+
+func TestFreezeWritePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("writing through a frozen pointer did not panic")
+		}
+	}()
+	x := new(int)
+	frozen := Freeze(x).(*int)
+	*frozen = 5
+}
@@ -0,0 +1,26 @@
+// Package mapstat reaches into the runtime's internal map representation
+// to answer questions the standard library has no API for: how many
+// buckets does this map have, how full are they, how long are its
+// overflow chains, is it mid-grow, and how far along is evacuation.
+//
+// It shares its approach (and its fragility) with [unsaferand]: the
+// layout is vendored per Go version behind build tags, and breaks the
+// moment a new Go release changes that layout. Unlike unsaferand it has
+// no fallback when the layout changes shape entirely - the Swiss Table
+// design Go 1.24 introduced doesn't have buckets, overflow chains, or an
+// evacuation counter in the same sense, so several of these stats are
+// approximated or trivially constant on that build; see each function's
+// doc comment for the go1.24 caveats.
+//
+// This is meant for poking at a live map or a heap dump while debugging
+// a map pathology (pathological hashing, a map that never shrinks,
+// growth stalls), not for production decision-making.
+package mapstat
+
+import "unsafe"
+
+// emptyInterface is the runtime representation of an interface{}.
+type emptyInterface struct {
+	typ unsafe.Pointer
+	val unsafe.Pointer
+}
@@ -0,0 +1,176 @@
+//go:build !go1.24
+
+package mapstat
+
+import "unsafe"
+
+// hmap mirrors runtime.hmap. We only ever read the fields through
+// nevacuate; Go 1.21 appended a clearSeq field after them for the
+// clear() builtin, which we never need and so never declare - its
+// existence doesn't shift the offsets of anything we do read.
+type hmap struct {
+	count      int
+	flags      uint8
+	B          uint8
+	noverflow  uint16
+	hash0      uint32
+	buckets    unsafe.Pointer
+	oldbuckets unsafe.Pointer
+	nevacuate  uintptr
+}
+
+type bmap struct {
+	tophash [bucketCnt]uint8
+}
+
+const (
+	bucketCntBits = 3
+	bucketCnt     = 1 << bucketCntBits
+
+	// sameSizeGrow is hmap.flags' bit for a grow that keeps B unchanged;
+	// oldbuckets then has 1<<B entries rather than 1<<(B-1).
+	sameSizeGrow = 8
+)
+
+// abiType mirrors the common prefix of internal/abi.Type, which every
+// Go type descriptor embeds. We need its full size so maptype's fields
+// below land at the right offsets, even though we never read abiType's
+// own fields.
+type abiType struct {
+	size       uintptr
+	ptrBytes   uintptr
+	hash       uint32
+	tflag      uint8
+	align      uint8
+	fieldAlign uint8
+	kind       uint8
+	equal      func(unsafe.Pointer, unsafe.Pointer) bool
+	gcdata     *byte
+	str        int32
+	ptrToThis  int32
+}
+
+// maptype mirrors internal/abi.OldMapType: enough to get from a bucket
+// to its bucketSize, which is all we need here.
+type maptype struct {
+	typ        abiType
+	key        unsafe.Pointer
+	elem       unsafe.Pointer
+	bucket     unsafe.Pointer
+	hasher     unsafe.Pointer
+	keySize    uint8
+	elemSize   uint8
+	bucketSize uint16
+}
+
+func hmapOf(m interface{}) (*maptype, *hmap) {
+	ei := *(*emptyInterface)(unsafe.Pointer(&m))
+	return (*maptype)(ei.typ), (*hmap)(ei.val)
+}
+
+func add(p unsafe.Pointer, x uintptr) unsafe.Pointer {
+	return unsafe.Pointer(uintptr(p) + x)
+}
+
+func bmapOverflow(b *bmap, bucketSize uintptr) *bmap {
+	return *(**bmap)(add(unsafe.Pointer(b), bucketSize-unsafe.Sizeof(uintptr(0))))
+}
+
+// Buckets returns the number of top-level buckets backing m, i.e. 2^B.
+// It does not count overflow buckets; see OverflowChains for those.
+func Buckets(m interface{}) int {
+	_, h := hmapOf(m)
+	if h == nil {
+		return 0
+	}
+	return 1 << h.B
+}
+
+// LoadFactor returns len(m) divided by the map's total slot capacity
+// (buckets * 8). The runtime grows a map once it averages more than 6.5
+// entries per bucket (its loadFactor constant), which on this scale is a
+// LoadFactor of 6.5/8.
+func LoadFactor(m interface{}) float64 {
+	_, h := hmapOf(m)
+	if h == nil {
+		return 0
+	}
+	return float64(h.count) / float64(int(1)<<h.B*bucketCnt)
+}
+
+// OverflowChains returns, for each top-level bucket, the number of
+// overflow buckets chained off of it (0 if the bucket has none).
+func OverflowChains(m interface{}) []int {
+	t, h := hmapOf(m)
+	if h == nil || h.buckets == nil {
+		return nil
+	}
+	bucketSize := uintptr(t.bucketSize)
+	n := 1 << h.B
+	chains := make([]int, n)
+	for i := 0; i < n; i++ {
+		b := (*bmap)(add(h.buckets, uintptr(i)*bucketSize))
+		for ob := bmapOverflow(b, bucketSize); ob != nil; ob = bmapOverflow(ob, bucketSize) {
+			chains[i]++
+		}
+	}
+	return chains
+}
+
+// TopHashHistogram counts how many slots across every bucket (including
+// overflow buckets, and oldbuckets if the map is mid-grow) hold each
+// possible tophash byte value. Values 0-3 are the reserved sentinels
+// (empty, evacuated-empty, evacuated-X, evacuated-Y); 4-255 are live
+// slots' cached hash bytes.
+func TopHashHistogram(m interface{}) [256]int {
+	t, h := hmapOf(m)
+	var hist [256]int
+	if h == nil {
+		return hist
+	}
+	bucketSize := uintptr(t.bucketSize)
+	tally := func(base unsafe.Pointer, n int) {
+		if base == nil {
+			return
+		}
+		for i := 0; i < n; i++ {
+			b := (*bmap)(add(base, uintptr(i)*bucketSize))
+			for ; b != nil; b = bmapOverflow(b, bucketSize) {
+				for _, th := range b.tophash {
+					hist[th]++
+				}
+			}
+		}
+	}
+	tally(h.buckets, 1<<h.B)
+	if h.oldbuckets != nil {
+		oldB := h.B - 1
+		if h.flags&sameSizeGrow != 0 {
+			oldB = h.B
+		}
+		tally(h.oldbuckets, 1<<oldB)
+	}
+	return hist
+}
+
+// Growing reports whether m is in the middle of an incremental grow,
+// i.e. still has an oldbuckets array awaiting evacuation.
+func Growing(m interface{}) bool {
+	_, h := hmapOf(m)
+	return h != nil && h.oldbuckets != nil
+}
+
+// EvacuationProgress returns the fraction of oldbuckets that have been
+// evacuated into the new bucket array, in [0,1]. It returns 1 for a map
+// that isn't growing, since there's nothing left to evacuate.
+func EvacuationProgress(m interface{}) float64 {
+	_, h := hmapOf(m)
+	if h == nil || h.oldbuckets == nil {
+		return 1
+	}
+	oldBuckets := 1 << (h.B - 1)
+	if h.flags&sameSizeGrow != 0 {
+		oldBuckets = 1 << h.B
+	}
+	return float64(h.nevacuate) / float64(oldBuckets)
+}
@@ -0,0 +1,198 @@
+//go:build go1.24
+
+package mapstat
+
+import "unsafe"
+
+// Map mirrors the head of internal/runtime/maps.Map, the Swiss Table
+// replacement for hmap. Growth in this design happens per-directory-entry
+// rather than as one global oldbuckets evacuation, so several stats below
+// (Growing, EvacuationProgress, OverflowChains) don't map onto this
+// layout the way they do pre-1.24; see their doc comments.
+type Map struct {
+	used        uint64
+	seed        uintptr
+	dirPtr      unsafe.Pointer
+	dirLen      int
+	globalDepth uint8
+	globalShift uint8
+	writing     uint8
+	clearSeq    uint64
+}
+
+// table mirrors internal/runtime/maps.table: one directory entry, sized
+// to hold a group of slots.
+type table struct {
+	used       uint16
+	capacity   uint16
+	growthLeft uint16
+	localDepth uint8
+	index      int
+	groups     groupsReference
+}
+
+type groupsReference struct {
+	data       unsafe.Pointer
+	lengthMask uint64
+}
+
+// ctrlGroupSize is the number of control bytes (and slots) per group.
+// Swiss Tables scan a whole group's control bytes in one SIMD compare.
+const ctrlGroupSize = 8
+
+// swissMapGroupSlots is abi.SwissMapGroupSlots: every group, regardless
+// of key/elem type, holds exactly this many slots behind its control
+// bytes. A "small map" (at most this many entries) skips the directory
+// entirely and stores that one group's slots inline via Map.dirPtr.
+const swissMapGroupSlots = 8
+
+// abiType mirrors the common prefix of internal/abi.Type, which every Go
+// type descriptor embeds. We only need its size field, but the struct
+// has to be laid out in full so swissMapType's pointers below land at
+// the right offsets.
+type abiType struct {
+	size       uintptr
+	ptrBytes   uintptr
+	hash       uint32
+	tflag      uint8
+	align      uint8
+	fieldAlign uint8
+	kind       uint8
+	equal      func(unsafe.Pointer, unsafe.Pointer) bool
+	gcdata     *byte
+	str        int32
+	ptrToThis  int32
+}
+
+// swissMapType mirrors only the head of internal/abi.SwissMapType, up
+// through Group: the type descriptor for one group (control bytes plus
+// swissMapGroupSlots slots), whose Size_ is the real per-group byte
+// stride. That stride depends on the map's key+elem size, so it can't be
+// hardcoded the way a fixed ctrlGroupSize-only stride would assume.
+type swissMapType struct {
+	typ   abiType
+	key   *abiType
+	elem  *abiType
+	group *abiType
+}
+
+func mapOf(m interface{}) (*Map, *swissMapType) {
+	ei := *(*emptyInterface)(unsafe.Pointer(&m))
+	return (*Map)(ei.val), (*swissMapType)(ei.typ)
+}
+
+// Buckets returns the number of tables currently in m's directory. It's
+// the closest Swiss Table analogue to a classic map's 2^B bucket count,
+// though unlike that count each table here can hold many groups' worth
+// of slots rather than exactly bucketCnt.
+//
+// A non-empty map with at most swissMapGroupSlots entries never
+// allocates a directory at all - it stores its single group inline via
+// Map.dirPtr, leaving dirLen at 0 - so that case is reported as one
+// (implicit) table rather than zero.
+func Buckets(m interface{}) int {
+	mm, _ := mapOf(m)
+	if mm == nil || mm.used == 0 {
+		return 0
+	}
+	if mm.dirLen == 0 {
+		return 1
+	}
+	return mm.dirLen
+}
+
+// LoadFactor returns used/capacity, where capacity sums every table's
+// slot capacity in the directory (or, for a small inline-group map,
+// swissMapGroupSlots).
+func LoadFactor(m interface{}) float64 {
+	mm, _ := mapOf(m)
+	if mm == nil || mm.used == 0 {
+		return 0
+	}
+	if mm.dirLen == 0 {
+		return float64(mm.used) / float64(swissMapGroupSlots)
+	}
+	dir := (*[1 << 20]*table)(mm.dirPtr)[:mm.dirLen:mm.dirLen]
+	seen := make(map[*table]bool, mm.dirLen)
+	var capacity uint64
+	for _, t := range dir {
+		if t == nil || seen[t] {
+			continue
+		}
+		seen[t] = true
+		capacity += uint64(t.capacity)
+	}
+	if capacity == 0 {
+		return 0
+	}
+	return float64(mm.used) / float64(capacity)
+}
+
+// OverflowChains always returns a slice of zeros sized to Buckets(m).
+// Swiss Tables resolve collisions by probing to the next group rather
+// than chaining overflow buckets, so there's no overflow-chain-length
+// stat to report; callers wanting to see probe pressure should compare
+// LoadFactor against the runtime's max load factor instead.
+func OverflowChains(m interface{}) []int {
+	return make([]int, Buckets(m))
+}
+
+// TopHashHistogram counts control bytes across every table's groups.
+// Swiss Tables' control bytes play the same role tophash did in classic
+// maps (empty/deleted sentinels plus a 7-bit hash fragment for full
+// slots), so the histogram shape is directly comparable across layouts.
+func TopHashHistogram(m interface{}) [256]int {
+	var hist [256]int
+	mm, mt := mapOf(m)
+	if mm == nil || mm.used == 0 {
+		return hist
+	}
+
+	// Each group occupies ctrlGroupSize control bytes followed by
+	// swissMapGroupSlots slots; slot size (and so the stride between one
+	// group and the next) depends on the map's key+elem size, which is
+	// exactly what mt.group.size already accounts for - it's the real
+	// runtime-computed size of one group for this map type.
+	groupStride := mt.group.size
+
+	tally := func(data unsafe.Pointer) {
+		ctrl := (*[ctrlGroupSize]byte)(data)
+		for _, c := range ctrl {
+			hist[c]++
+		}
+	}
+
+	if mm.dirLen == 0 {
+		// Small map: one inline group, no directory/table allocated.
+		tally(mm.dirPtr)
+		return hist
+	}
+
+	dir := (*[1 << 20]*table)(mm.dirPtr)[:mm.dirLen:mm.dirLen]
+	seen := make(map[*table]bool, mm.dirLen)
+	for _, t := range dir {
+		if t == nil || seen[t] {
+			continue
+		}
+		seen[t] = true
+		groups := int(t.groups.lengthMask) + 1
+		for g := 0; g < groups; g++ {
+			tally(unsafe.Pointer(uintptr(t.groups.data) + uintptr(g)*groupStride))
+		}
+	}
+	return hist
+}
+
+// Growing always returns false. Swiss Tables grow one table at a time,
+// synchronously, rather than leaving a persistent mid-grow state (like
+// classic maps' oldbuckets) observable between writes, so there's no
+// "is this map currently growing" bit to read.
+func Growing(m interface{}) bool {
+	return false
+}
+
+// EvacuationProgress always returns 1. There's no incremental evacuation
+// step in the Swiss Table design for it to measure progress against.
+func EvacuationProgress(m interface{}) float64 {
+	return 1
+}
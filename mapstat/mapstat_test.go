@@ -0,0 +1,67 @@
+package mapstat
+
+import "testing"
+
+// TestBasicStats sanity-checks the diagnostics against a map big enough
+// to force at least one grow, so buckets/load factor/tophash histogram/
+// evacuation progress all have something non-trivial to report.
+func TestBasicStats(t *testing.T) {
+	m := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		m[i] = i
+	}
+
+	if got := Buckets(m); got <= 0 {
+		t.Errorf("Buckets = %d, want > 0", got)
+	}
+	if lf := LoadFactor(m); lf <= 0 || lf > 1 {
+		t.Errorf("LoadFactor = %v, want in (0,1]", lf)
+	}
+
+	hist := TopHashHistogram(m)
+	var total int
+	for _, c := range hist {
+		total += c
+	}
+	if total <= 0 {
+		t.Errorf("TopHashHistogram total = %d, want > 0", total)
+	}
+
+	if ep := EvacuationProgress(m); ep < 0 || ep > 1 {
+		t.Errorf("EvacuationProgress = %v, want in [0,1]", ep)
+	}
+}
+
+// TestSmallMap checks a map small enough that some map implementations
+// (Go 1.24's Swiss Table among them) store it inline instead of
+// allocating a directory/bucket array, to make sure that representation
+// isn't mistaken for an empty map.
+func TestSmallMap(t *testing.T) {
+	m := map[int]int{1: 1, 2: 2, 3: 3}
+
+	if got := Buckets(m); got <= 0 {
+		t.Errorf("Buckets = %d, want > 0", got)
+	}
+	if lf := LoadFactor(m); lf <= 0 || lf > 1 {
+		t.Errorf("LoadFactor = %v, want in (0,1]", lf)
+	}
+
+	hist := TopHashHistogram(m)
+	var total int
+	for _, c := range hist {
+		total += c
+	}
+	if total <= 0 {
+		t.Errorf("TopHashHistogram total = %d, want > 0", total)
+	}
+}
+
+func TestEmptyMap(t *testing.T) {
+	var m map[int]int
+	if got := Buckets(m); got != 0 {
+		t.Errorf("Buckets(nil map) = %d, want 0", got)
+	}
+	if got := Growing(m); got {
+		t.Errorf("Growing(nil map) = true, want false")
+	}
+}
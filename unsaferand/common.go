@@ -0,0 +1,114 @@
+package unsaferand
+
+import "unsafe"
+
+// emptyInterface is the runtime representation of an interface{}.
+type emptyInterface struct {
+	typ unsafe.Pointer
+	val unsafe.Pointer
+}
+
+// mapIter is implemented once per Go-version build tag (legacy.go,
+// go121.go, go124.go) over that version's map layout.
+type mapIter interface {
+	// len reports the number of live entries in the map.
+	len() int
+	// next advances the iterator and returns the current key, or
+	// ok == false once iteration is exhausted.
+	next() (key unsafe.Pointer, ok bool)
+	// keyType returns the type descriptor for the map's key type, so
+	// the raw key bytes can be boxed back into an interface{}.
+	keyType() unsafe.Pointer
+	// uniformKey draws a single key such that every live key has equal
+	// probability of being returned, regardless of bucket fill or
+	// overflow chain length. See uniform.go for how this differs from
+	// walking next().
+	uniformKey() unsafe.Pointer
+}
+
+// newMapIter is implemented by exactly one of legacy.go, go121.go, or
+// go124.go, selected at compile time by the active build tag.
+
+// add returns p+x. Shorthand used throughout the bucket-walking code
+// below, mirroring runtime.add.
+func add(p unsafe.Pointer, x uintptr) unsafe.Pointer {
+	return unsafe.Pointer(uintptr(p) + x)
+}
+
+// Bucket layout shared by the classic (pre-Swiss-Table) hmap across every
+// era covered by legacy.go and go121.go: bucketCnt slots of tophash,
+// followed by the keys, then the values, then an overflow pointer. Only
+// hmap/hiter itself (and the fields maptype exposes) changed between
+// those two eras.
+const (
+	bucketCntBits = 3
+	bucketCnt     = 1 << bucketCntBits
+
+	tophashEmpty          = 0 // cell is empty
+	tophashEvacuatedEmpty = 1 // cell is empty, bucket is evacuated
+	tophashEvacuatedX     = 2 // key/value valid, evacuated to first half of larger table
+	tophashEvacuatedY     = 3 // key/value valid, evacuated to second half of larger table
+	tophashMinTopHash     = 4 // minimum tophash for a normal filled cell
+
+	// sameSizeGrow is hmap.flags' bit marking a grow that didn't double B:
+	// triggered by excessive overflow-bucket buildup rather than the load
+	// factor, it re-buckets into an array the same size as the old one.
+	// oldbuckets during such a grow has 1<<h.B entries, not 1<<(h.B-1).
+	sameSizeGrow = 8
+)
+
+type bmap struct {
+	tophash [bucketCnt]uint8
+	// followed by bucketCnt keys, then bucketCnt values, then an
+	// overflow *bmap; sizes depend on the map's key/value types so we
+	// can't express them as Go struct fields.
+}
+
+// dataOffset is the byte offset of the first key in a bucket, which is
+// sizeof(bmap) rounded up to bmap's alignment (a pointer, because of the
+// trailing overflow pointer).
+const dataOffset = unsafe.Offsetof(struct {
+	b bmap
+	v int64
+}{}.v)
+
+func evacuated(b *bmap) bool {
+	h := b.tophash[0]
+	return h > tophashEmpty && h < tophashMinTopHash
+}
+
+// bmapOverflow reads the overflow pointer appended after a bucket's
+// key/value data. bucketSize is maptype.bucketSize (or equivalent) for
+// the map in question.
+func bmapOverflow(b *bmap, bucketSize uintptr) *bmap {
+	return *(**bmap)(add(unsafe.Pointer(b), bucketSize-unsafe.Sizeof(uintptr(0))))
+}
+
+// chainLenAt returns the length of the overflow chain rooted at the i'th
+// bucket in the bucketSize-sized bucket array starting at base (1 if
+// that bucket has no overflow buckets).
+func chainLenAt(base unsafe.Pointer, i int, bucketSize uintptr) int {
+	b := (*bmap)(add(base, uintptr(i)*bucketSize))
+	n := 1
+	for ob := bmapOverflow(b, bucketSize); ob != nil; ob = bmapOverflow(ob, bucketSize) {
+		n++
+	}
+	return n
+}
+
+// maxChainLenAt returns the longest overflow chain among the n buckets
+// starting at base. uniformKey needs this across the *whole* table
+// (both the new bucket array and, mid-grow, the old one) rather than
+// just the one bucket it happens to land on: normalizing the slot space
+// to each chosen bucket's own chain length, as an earlier version of
+// this package did, would make a key sharing a long overflow chain less
+// likely to be drawn than one sitting alone in a short bucket.
+func maxChainLenAt(base unsafe.Pointer, n int, bucketSize uintptr) int {
+	max := 1
+	for i := 0; i < n; i++ {
+		if l := chainLenAt(base, i, bucketSize); l > max {
+			max = l
+		}
+	}
+	return max
+}
@@ -0,0 +1,19 @@
+// Package unsaferand picks a random key out of a map by reaching into
+// the runtime's internal map representation: MapKey advances the
+// runtime's own map iterator a random number of steps, and
+// UniformMapKey samples the bucket array directly where the map layout
+// has one.
+//
+// The runtime's map layout is not part of the language spec and has
+// changed more than once: buckets moved fields around between 1.17 and
+// 1.20, gained a clearSeq counter in 1.21 for the clear() builtin, and
+// were replaced outright by a Swiss Table design in 1.24. This package
+// vendors the relevant struct definitions for each era behind build
+// tags and reaches into the runtime with go:linkname where needed, so
+// MapKey keeps working (or fails to compile loudly) as the toolchain
+// moves instead of silently reading garbage.
+//
+// This is exactly the kind of thing the unsafe package warns you about:
+// every file here is coupled to unexported runtime internals and can
+// break on any Go release. Use it for demos, not production code.
+package unsaferand
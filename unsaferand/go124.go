@@ -0,0 +1,140 @@
+//go:build go1.24
+
+package unsaferand
+
+import (
+	"math/rand"
+	"unsafe"
+)
+
+// Go 1.24 replaced the bucketed hmap with a Swiss Table design living in
+// internal/runtime/maps: buckets became "groups" scanned via control-byte
+// SIMD comparisons, and there is no longer a flat bmap array to walk.
+//
+// Because so much code in the wild reaches into runtime.hmap/hiter via
+// go:linkname (this package included), the runtime team kept
+// mapiterinit/mapiternext around as compatibility shims: they still
+// accept the pre-1.24 hiter shape and populate it from the new Map/table
+// internals under the hood. We ride those shims instead of re-vendoring
+// the group/control-byte layout directly, which would be considerably
+// more fragile and isn't exposed in a linkname-friendly way. runtime.hiter
+// is in fact just an alias for internal/runtime/maps.Iter, so the field
+// order and sizes below have to match that type exactly - in particular
+// tab and group are real pointers (the GC's bitmap for this struct is
+// derived from these field types), not the uint64/[2]unsafe.Pointer an
+// earlier version of this file used, which left tab unscanned.
+type hiter struct {
+	key         unsafe.Pointer
+	elem        unsafe.Pointer
+	typ         unsafe.Pointer // *abi.SwissMapType, opaque to us
+	m           unsafe.Pointer // *maps.Map, opaque to us
+	entryOffset uint64
+	dirOffset   uint64
+	clearSeq    uint64
+	globalDepth uint8
+	dirIdx      int
+	tab         unsafe.Pointer // *maps.table, opaque to us
+	group       unsafe.Pointer // maps.groupReference, opaque to us
+	entryIdx    uint64
+}
+
+//go:linkname mapiterinit runtime.mapiterinit
+func mapiterinit(t unsafe.Pointer, m unsafe.Pointer, it *hiter)
+
+//go:linkname mapiternext runtime.mapiternext
+func mapiternext(it *hiter)
+
+// mapHeader mirrors the head of internal/runtime/maps.Map, just enough to
+// read used (the live entry count) directly. There is no runtime.maplen
+// symbol to go:linkname on 1.24+ - the compiler computes len(m) from the
+// map header inline instead - so we read the field ourselves the same way
+// mapstat/go124.go does, rather than linknaming into reflect.maplen.
+type mapHeader struct {
+	used        uint64
+	seed        uintptr
+	dirPtr      unsafe.Pointer
+	dirLen      int
+	globalDepth uint8
+	globalShift uint8
+	writing     uint8
+	clearSeq    uint64
+}
+
+// abiType mirrors the common prefix of internal/abi.Type.
+type abiType struct {
+	size       uintptr
+	ptrBytes   uintptr
+	hash       uint32
+	tflag      uint8
+	align      uint8
+	fieldAlign uint8
+	kind       uint8
+	equal      func(unsafe.Pointer, unsafe.Pointer) bool
+	gcdata     *byte
+	str        int32
+	ptrToThis  int32
+}
+
+// swissMapType mirrors only the head of internal/abi.SwissMapType: enough
+// to read the Key type descriptor back out, which is all MapKey needs to
+// box the raw key bytes into an interface{}. The Group/Hasher/Slot fields
+// that follow are never read here.
+type swissMapType struct {
+	typ  abiType
+	key  *abiType
+	elem *abiType
+}
+
+type go124Iter struct {
+	it     *hiter
+	n      int
+	keyTyp unsafe.Pointer
+}
+
+func newMapIter(ei emptyInterface) mapIter {
+	it := new(hiter)
+	mapiterinit(ei.typ, ei.val, it)
+	return &go124Iter{
+		it:     it,
+		n:      int((*mapHeader)(ei.val).used),
+		keyTyp: unsafe.Pointer((*swissMapType)(ei.typ).key),
+	}
+}
+
+func (m *go124Iter) len() int { return m.n }
+
+func (m *go124Iter) next() (unsafe.Pointer, bool) {
+	if m.it.key == nil {
+		return nil, false
+	}
+	key := m.it.key
+	mapiternext(m.it)
+	return key, true
+}
+
+func (m *go124Iter) keyType() unsafe.Pointer { return m.keyTyp }
+
+// uniformKey draws a uniform key via reservoir sampling over a full
+// pass of the map, rather than the bucket-rejection trick legacy.go and
+// go121.go use. Swiss Table groups don't expose a flat "bucket index"
+// the way classic hmap buckets do - slots live behind SIMD-scanned
+// control bytes inside groups inside a directory of tables - so there's
+// no equivalently cheap O(1) sampling scheme available through the
+// mapiterinit/mapiternext compat shim. The pass costs O(n), the same
+// order as MapKey's walk of up to n iterator steps, and reservoir
+// sampling over it is the simplest correct option.
+func (m *go124Iter) uniformKey() unsafe.Pointer {
+	var chosen unsafe.Pointer
+	n := 0
+	for {
+		key, ok := m.next()
+		if !ok {
+			break
+		}
+		n++
+		if rand.Intn(n) == 0 {
+			chosen = key
+		}
+	}
+	return chosen
+}
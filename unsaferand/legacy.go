@@ -0,0 +1,200 @@
+//go:build !go1.21
+
+package unsaferand
+
+import (
+	"math/rand"
+	"unsafe"
+)
+
+// abiType mirrors the common prefix of internal/abi.Type, which every
+// Go type descriptor embeds regardless of kind.
+type abiType struct {
+	size       uintptr
+	ptrBytes   uintptr
+	hash       uint32
+	tflag      uint8
+	align      uint8
+	fieldAlign uint8
+	kind       uint8
+	equal      func(unsafe.Pointer, unsafe.Pointer) bool
+	gcdata     *byte
+	str        int32
+	ptrToThis  int32
+}
+
+// maptype mirrors internal/abi.OldMapType as of Go 1.17-1.20.
+type maptype struct {
+	typ        abiType
+	key        *abiType
+	elem       *abiType
+	bucket     *abiType
+	hasher     func(unsafe.Pointer, uintptr) uintptr
+	keySize    uint8
+	elemSize   uint8
+	bucketSize uint16
+	flags      uint32
+}
+
+// hmap mirrors runtime.hmap as of Go 1.17-1.20, i.e. before the
+// clearSeq field was added for the clear() builtin in Go 1.21.
+type hmap struct {
+	count      int
+	flags      uint8
+	B          uint8
+	noverflow  uint16
+	hash0      uint32
+	buckets    unsafe.Pointer
+	oldbuckets unsafe.Pointer
+	nevacuate  uintptr
+	extra      unsafe.Pointer
+}
+
+// hiter mirrors runtime.hiter. Its field order and size are load-bearing:
+// reflect relies on //go:linkname'd copies of mapiterinit/mapiternext
+// writing through exactly this layout, which is the only reason this
+// trick still works at all.
+type hiter struct {
+	key         unsafe.Pointer
+	elem        unsafe.Pointer
+	t           *maptype
+	h           *hmap
+	buckets     unsafe.Pointer
+	bptr        unsafe.Pointer
+	overflow    [2]unsafe.Pointer
+	startBucket uintptr
+	offset      uint8
+	wrapped     bool
+	B           uint8
+	i           uint8
+	bucket      uintptr
+	checkBucket uintptr
+}
+
+//go:linkname mapiterinit runtime.mapiterinit
+func mapiterinit(t *maptype, m unsafe.Pointer, it *hiter)
+
+//go:linkname mapiternext runtime.mapiternext
+func mapiternext(it *hiter)
+
+type legacyIter struct {
+	t  *maptype
+	h  *hmap
+	it *hiter
+}
+
+func newMapIter(ei emptyInterface) mapIter {
+	t := (*maptype)(ei.typ)
+	h := (*hmap)(ei.val)
+	it := new(hiter)
+	mapiterinit(t, ei.val, it)
+	return &legacyIter{t: t, h: h, it: it}
+}
+
+func (m *legacyIter) len() int { return m.h.count }
+
+func (m *legacyIter) next() (unsafe.Pointer, bool) {
+	if m.it.key == nil {
+		return nil, false
+	}
+	key := m.it.key
+	mapiternext(m.it)
+	return key, true
+}
+
+func (m *legacyIter) keyType() unsafe.Pointer {
+	return unsafe.Pointer(m.it.t.key)
+}
+
+// uniformKey samples (bucket, slot) pairs directly instead of walking the
+// iterator; see UniformMapKey's doc comment for why. It assumes inline
+// (non-indirect) keys, which covers the common case this package targets
+// (small comparable keys); maps with oversized keys that the runtime
+// stores as pointers aren't supported here.
+func (m *legacyIter) uniformKey() unsafe.Pointer {
+	t, h := m.t, m.h
+	bucketSize := uintptr(t.bucketSize)
+
+	// Compute the table-wide longest overflow chain once, across both
+	// the new bucket array and (mid-grow) the old one. Every draw below
+	// samples a slot against this shared maximum rather than against
+	// whichever bucket it happens to land on, which is what makes the
+	// result uniform regardless of fill - see maxChainLenAt.
+	// A same-size grow (triggered by overflow-bucket buildup rather than
+	// load factor) leaves oldbuckets the same size as the new array
+	// instead of half of it; h.B must not be decremented when sizing it
+	// in that case, or up to half the old table's live keys are silently
+	// excluded from every draw.
+	oldB := h.B - 1
+	if h.flags&sameSizeGrow != 0 {
+		oldB = h.B
+	}
+
+	maxChain := maxChainLenAt(h.buckets, 1<<h.B, bucketSize)
+	if h.oldbuckets != nil {
+		if l := maxChainLenAt(h.oldbuckets, 1<<oldB, bucketSize); l > maxChain {
+			maxChain = l
+		}
+	}
+
+	for {
+		bucketIdx := uintptr(rand.Int63n(int64(1) << h.B))
+		b := (*bmap)(add(h.buckets, bucketIdx*bucketSize))
+		checking := false
+
+		if h.oldbuckets != nil {
+			oldIdx := bucketIdx & (uintptr(1)<<oldB - 1)
+			oldb := (*bmap)(add(h.oldbuckets, oldIdx*bucketSize))
+			if !evacuated(oldb) {
+				// This new bucket's data hasn't been evacuated out of
+				// its old bucket yet; sample from the old bucket and
+				// reject keys bound for the other half once we hash them.
+				b = oldb
+				checking = true
+			}
+		}
+
+		// Pick a slot uniformly across the table-wide maximum chain
+		// length and reject the whole draw outright if it runs past
+		// this particular bucket's (possibly shorter) actual chain,
+		// rather than narrowing the slot space to fit. That rejection
+		// is what keeps a key in a long chain from being
+		// under-sampled relative to one sitting alone in a bucket with
+		// no overflow at all.
+		slot := rand.Intn(bucketCnt * maxChain)
+		cur := b
+		for slot >= bucketCnt {
+			cur = bmapOverflow(cur, bucketSize)
+			if cur == nil {
+				break
+			}
+			slot -= bucketCnt
+		}
+		if cur == nil {
+			continue
+		}
+
+		top := cur.tophash[slot]
+		if top == tophashEmpty || top == tophashEvacuatedEmpty {
+			continue
+		}
+
+		k := add(unsafe.Pointer(cur), dataOffset+uintptr(slot)*uintptr(t.keySize))
+		if checking {
+			hash := t.hasher(k, uintptr(h.hash0))
+			if hash&(uintptr(1)<<h.B-1) != bucketIdx {
+				// This key is still waiting to be evacuated to the
+				// *other* new bucket; it doesn't belong to bucketIdx.
+				continue
+			}
+		}
+		if top == tophashEvacuatedX || top == tophashEvacuatedY {
+			// Stale slot left behind by a finished evacuation; the
+			// live copy is elsewhere in the table. Retry rather than
+			// chase it, since a fresh draw is just as uniform.
+			continue
+		}
+
+		return k
+	}
+}
@@ -0,0 +1,39 @@
+package unsaferand
+
+import (
+	"math/rand"
+	"unsafe"
+)
+
+// MapKey returns a key chosen pseudo-randomly from m, instead of taking
+// the first key visited by a range loop (`for k := range m { break }`,
+// the pattern this package replaces, which favours some keys over
+// others). It draws r uniformly from [0, len(m)) and returns the r'th
+// key the runtime's map iterator visits, so a draw costs O(r) iterator
+// steps.
+//
+// m must be a non-nil map with at least one entry, or MapKey panics.
+func MapKey(m interface{}) interface{} {
+	ei := *(*emptyInterface)(unsafe.Pointer(&m))
+	it := newMapIter(ei)
+
+	n := it.len()
+	if n == 0 {
+		panic("unsaferand: MapKey called on an empty map")
+	}
+
+	r := rand.Intn(n)
+	var key unsafe.Pointer
+	for i := 0; i <= r; i++ {
+		k, ok := it.next()
+		if !ok {
+			panic("unsaferand: map iterator exhausted before reaching the chosen index")
+		}
+		key = k
+	}
+
+	return *(*interface{})(unsafe.Pointer(&emptyInterface{
+		typ: it.keyType(),
+		val: key,
+	}))
+}
@@ -0,0 +1,39 @@
+package unsaferand
+
+import "testing"
+
+// TestMapKeyDistribution seeds a small map and checks that MapKey visits
+// every key with roughly equal frequency. It runs against whichever
+// build-tagged hmap/hiter layout matches the Go version under test, so
+// the same test doubles as the per-version regression check called for
+// when a new layout variant is added.
+func TestMapKeyDistribution(t *testing.T) {
+	const keys = 5
+	const draws = 100000
+
+	m := make(map[int]struct{}, keys)
+	for i := 0; i < keys; i++ {
+		m[i] = struct{}{}
+	}
+
+	var counts [keys]int
+	for i := 0; i < draws; i++ {
+		counts[MapKey(m).(int)]++
+	}
+
+	want := float64(draws) / keys
+	for k, c := range counts {
+		if deviation := (float64(c) - want) / want; deviation < -0.1 || deviation > 0.1 {
+			t.Errorf("key %d: got %d draws, want ~%v (>10%% off)", k, c, want)
+		}
+	}
+}
+
+func TestMapKeyEmptyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MapKey of empty map did not panic")
+		}
+	}()
+	MapKey(map[int]int{})
+}
@@ -0,0 +1,42 @@
+package unsaferand
+
+import "unsafe"
+
+// UniformMapKey returns a key drawn uniformly at random from m: every
+// live key has probability exactly 1/len(m) of being chosen, regardless
+// of how keys happen to be distributed across buckets.
+//
+// MapKey reaches the same distribution the slow way. It picks a random
+// index r and advances the runtime's map iterator r times; the iterator
+// visits every live key exactly once whatever its randomized starting
+// bucket and offset, so the key at a uniformly random position is
+// uniform too, but reaching it costs O(r) iterator steps.
+//
+// UniformMapKey instead samples buckets directly: pick a random bucket
+// (and, mid-grow, resolve it back to the correct half of its old
+// bucket), pick a random slot in [0, 8*L) where L is the longest
+// overflow chain anywhere in the table, and reject-and-retry if the slot
+// lies past the end of this bucket's chain, turned out to be empty, or
+// holds a key that doesn't actually belong to the half of the table we
+// asked for. Because every live slot in the table has the same chance of
+// being the winning (bucket, slot) pair, the result is uniform
+// irrespective of fill. On Go 1.24 and later, whose maps have no
+// buckets, it takes one full pass over the map instead and keeps a key
+// by reservoir sampling.
+//
+// m must be a non-nil map with at least one entry, or UniformMapKey
+// panics.
+func UniformMapKey(m interface{}) interface{} {
+	ei := *(*emptyInterface)(unsafe.Pointer(&m))
+	it := newMapIter(ei)
+
+	if it.len() == 0 {
+		panic("unsaferand: UniformMapKey called on an empty map")
+	}
+
+	key := it.uniformKey()
+	return *(*interface{})(unsafe.Pointer(&emptyInterface{
+		typ: it.keyType(),
+		val: key,
+	}))
+}
@@ -0,0 +1,49 @@
+package unsaferand
+
+import (
+	"math"
+	"testing"
+)
+
+// TestUniformMapKeyChiSquare builds a map large enough that on the
+// classic layout it has buckets with varying overflow chain lengths for
+// practically every hash seed (a 2000-entry size hint gives 512 8-slot
+// buckets, about 3.9 keys each, and the chance that none of them
+// overflows is well under one in ten thousand). An earlier version of this test used only 40 keys, which only
+// exercised a chain-length-dependent bias when the random hash seed
+// happened to produce an overflow bucket; that made the test pass or
+// fail on unrelated seed luck instead of reliably catching the bias it
+// claims to test for. It then checks the draw distribution against a
+// chi-square goodness-of-fit test.
+func TestUniformMapKeyChiSquare(t *testing.T) {
+	const keys = 2000
+	const draws = 1000000
+
+	m := make(map[int]struct{}, keys)
+	for i := 0; i < keys; i++ {
+		m[i] = struct{}{}
+	}
+
+	counts := make([]int, keys)
+	for i := 0; i < draws; i++ {
+		counts[UniformMapKey(m).(int)]++
+	}
+
+	expected := float64(draws) / float64(keys)
+	chiSquare := 0.0
+	for _, c := range counts {
+		d := float64(c) - expected
+		chiSquare += d * d / expected
+	}
+
+	// 1999 degrees of freedom; critical value at p=0.001 is ~2194. Use a
+	// generous margin above that since this is a randomized test we
+	// don't want flaking on CI.
+	const criticalValue = 2400.0
+	if chiSquare > criticalValue {
+		t.Errorf("chi-square statistic %.2f exceeds critical value %.2f; draws are not uniform", chiSquare, criticalValue)
+	}
+	if math.IsNaN(chiSquare) {
+		t.Fatal("chi-square statistic is NaN")
+	}
+}